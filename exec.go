@@ -0,0 +1,264 @@
+package astikit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ExecStatus represents the status of a process started through Worker.Exec
+type ExecStatus string
+
+// Exec statuses
+const (
+	ExecStatusRunning ExecStatus = "running"
+	ExecStatusStopped ExecStatus = "stopped"
+	ExecStatusCrashed ExecStatus = "crashed"
+)
+
+// ExecRestartPolicy indicates whether and when a process started through
+// Worker.Exec should be restarted once it exits
+type ExecRestartPolicy int
+
+// Exec restart policies
+const (
+	// ExecRestartPolicyNever never restarts the process
+	ExecRestartPolicyNever ExecRestartPolicy = iota
+	// ExecRestartPolicyOnFailure restarts the process only when it exits with an error
+	ExecRestartPolicyOnFailure
+	// ExecRestartPolicyAlways always restarts the process once it exits
+	ExecRestartPolicyAlways
+)
+
+// DefaultExecRestartBackoff is used when ExecOptions.RestartBackoff is not set
+const DefaultExecRestartBackoff = time.Second
+
+// DefaultExecStopTimeout is used when ExecOptions.StopTimeout is not set
+const DefaultExecStopTimeout = 5 * time.Second
+
+// ExecOptions represents options usable in Worker.ExecWithOptions
+type ExecOptions struct {
+	// RestartPolicy indicates whether the process should be restarted once it exits.
+	// Defaults to ExecRestartPolicyNever
+	RestartPolicy ExecRestartPolicy
+	// RestartBackoff is the delay waited before restarting the process. Defaults to
+	// DefaultExecRestartBackoff
+	RestartBackoff time.Duration
+	// StopTimeout is how long Stop waits after sending SIGTERM before sending SIGKILL.
+	// Defaults to DefaultExecStopTimeout
+	StopTimeout time.Duration
+}
+
+// ExecHandler lets you control and inspect a process started through Worker.Exec
+type ExecHandler struct {
+	args     []string
+	cmd      *exec.Cmd
+	done     chan struct{}
+	exited   chan struct{}
+	m        sync.Mutex
+	name     string
+	o        ExecOptions
+	sos      sync.Once
+	status   ExecStatus
+	stopping bool
+	task     *Task
+	w        *Worker
+}
+
+// Exec starts name with args as a managed subprocess and returns a handler to
+// control and inspect it. The process is killed when the worker stops.
+func (w *Worker) Exec(name string, args ...string) (*ExecHandler, error) {
+	return w.ExecWithOptions(ExecOptions{}, name, args...)
+}
+
+// ExecWithOptions is the same as Exec except it allows specifying ExecOptions
+func (w *Worker) ExecWithOptions(o ExecOptions, name string, args ...string) (*ExecHandler, error) {
+	// Set default options
+	if o.RestartBackoff <= 0 {
+		o.RestartBackoff = DefaultExecRestartBackoff
+	}
+	if o.StopTimeout <= 0 {
+		o.StopTimeout = DefaultExecStopTimeout
+	}
+
+	// Create handler
+	h := &ExecHandler{
+		args: args,
+		done: make(chan struct{}),
+		name: name,
+		o:    o,
+		w:    w,
+	}
+
+	// Start
+	if err := h.start(); err != nil {
+		return nil, fmt.Errorf("astikit: starting %s failed: %w", name, err)
+	}
+
+	// Run in a task so the worker waits for it
+	h.task = w.NewTask()
+	h.task.Do(h.run)
+
+	// Stop when the worker stops
+	w.NewTask().Do(func() {
+		<-w.Context().Done()
+		h.Stop()
+	})
+	return h, nil
+}
+
+func (h *ExecHandler) start() error {
+	// Create cmd
+	cmd := exec.Command(h.name, h.args...)
+	cmd.Stdout = newExecLineWriter(h.w.Logger().Debugf)
+	cmd.Stderr = newExecLineWriter(h.w.Logger().Errorf)
+
+	// Start
+	h.w.Logger().Debugf("astikit: starting %s...", h.name)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// Update
+	h.m.Lock()
+	h.cmd = cmd
+	h.exited = make(chan struct{})
+	h.status = ExecStatusRunning
+	h.m.Unlock()
+	return nil
+}
+
+func (h *ExecHandler) run() {
+	defer close(h.done)
+
+	for {
+		// Wait for the process to exit
+		err := h.cmd.Wait()
+
+		h.m.Lock()
+		stopping := h.stopping
+		if err != nil {
+			h.status = ExecStatusCrashed
+		} else {
+			h.status = ExecStatusStopped
+		}
+		close(h.exited)
+		h.m.Unlock()
+
+		// Stop() is in charge from here on
+		if stopping {
+			return
+		}
+
+		// Check restart policy
+		if h.o.RestartPolicy != ExecRestartPolicyAlways &&
+			!(h.o.RestartPolicy == ExecRestartPolicyOnFailure && err != nil) {
+			return
+		}
+
+		// Backoff
+		select {
+		case <-h.w.Context().Done():
+			return
+		case <-time.After(h.o.RestartBackoff):
+		}
+
+		// Stop() may have been called during the backoff, against the
+		// process we just waited on: recheck before actually restarting
+		h.m.Lock()
+		stopping = h.stopping
+		h.m.Unlock()
+		if stopping {
+			return
+		}
+
+		// Restart
+		h.w.Logger().Debugf("astikit: restarting %s...", h.name)
+		if err := h.start(); err != nil {
+			h.w.Logger().Errorf("astikit: restarting %s failed: %w", h.name, err)
+			return
+		}
+	}
+}
+
+// Status returns the process' current status
+func (h *ExecHandler) Status() ExecStatus {
+	h.m.Lock()
+	defer h.m.Unlock()
+	return h.status
+}
+
+// Stop sends SIGTERM to the process and, if it hasn't exited after
+// StopTimeout, SIGKILL. It also prevents any further restart.
+func (h *ExecHandler) Stop() {
+	h.sos.Do(func() {
+		h.m.Lock()
+		h.stopping = true
+		h.m.Unlock()
+
+		// Loop in case run() raced us and restarted the process right
+		// before observing h.stopping: keep targeting whatever is current
+		// until it stops changing
+		for {
+			h.m.Lock()
+			cmd, exited := h.cmd, h.exited
+			h.m.Unlock()
+
+			if cmd == nil || cmd.Process == nil {
+				return
+			}
+
+			h.w.Logger().Debugf("astikit: stopping %s...", h.name)
+			_ = cmd.Process.Signal(syscall.SIGTERM)
+
+			select {
+			case <-exited:
+			case <-time.After(h.o.StopTimeout):
+				h.w.Logger().Debugf("astikit: %s didn't stop in time, killing it", h.name)
+				_ = cmd.Process.Kill()
+				<-exited
+			}
+
+			h.m.Lock()
+			restarted := h.cmd != cmd
+			h.m.Unlock()
+			if !restarted {
+				return
+			}
+		}
+	})
+}
+
+// Wait blocks until the process (and any restarts) is done
+func (h *ExecHandler) Wait() {
+	<-h.done
+}
+
+// execLineWriter is an io.Writer that logs every line written to it using logf
+type execLineWriter struct {
+	buf  []byte
+	logf func(format string, v ...interface{})
+}
+
+// newExecLineWriter returns an io.Writer that logs every line written to it
+// using logf
+func newExecLineWriter(logf func(format string, v ...interface{})) io.Writer {
+	return &execLineWriter{logf: logf}
+}
+
+func (w *execLineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.logf("%s", bytes.TrimRight(w.buf[:i], "\r"))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
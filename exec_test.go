@@ -0,0 +1,67 @@
+package astikit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestExecHandlerStopDuringBackoff makes sure Stop() called while a restart
+// is backing off actually prevents that restart, instead of being a no-op
+// against the process that already exited.
+func TestExecHandlerStopDuringBackoff(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "marker")
+
+	w := NewWorker(nil)
+	h, err := w.ExecWithOptions(ExecOptions{
+		RestartPolicy:  ExecRestartPolicyAlways,
+		RestartBackoff: 200 * time.Millisecond,
+	}, "sh", "-c", "echo run >> "+marker)
+	if err != nil {
+		t.Fatalf("ExecWithOptions failed: %v", err)
+	}
+
+	// Let the first run complete and enter the backoff window, then stop
+	// well before the backoff elapses
+	time.Sleep(50 * time.Millisecond)
+	h.Stop()
+	h.Wait()
+
+	// Give a slipped-through restart a chance to happen
+	time.Sleep(400 * time.Millisecond)
+
+	b, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("reading marker failed: %v", err)
+	}
+	if got := countLines(b); got != 1 {
+		t.Fatalf("expected the process to run exactly once, ran %d times", got)
+	}
+}
+
+// TestExecHandlerWaitBlocksUntilExit makes sure Wait() actually blocks until
+// the process exits instead of returning as soon as the handler is set up.
+func TestExecHandlerWaitBlocksUntilExit(t *testing.T) {
+	w := NewWorker(nil)
+	h, err := w.ExecWithOptions(ExecOptions{}, "sh", "-c", "sleep 0.3")
+	if err != nil {
+		t.Fatalf("ExecWithOptions failed: %v", err)
+	}
+
+	start := time.Now()
+	h.Wait()
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Fatalf("Wait() returned after %s, expected it to block until the process exited", elapsed)
+	}
+}
+
+func countLines(b []byte) (n int) {
+	for _, c := range b {
+		if c == '\n' {
+			n++
+		}
+	}
+	return
+}
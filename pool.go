@@ -0,0 +1,147 @@
+package astikit
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrWorkerPoolClosed is returned by SubmitContext once the pool has been
+// (or is being) closed
+var ErrWorkerPoolClosed = errors.New("astikit: worker pool is closed")
+
+// WorkerPoolOptions configures NewWorkerPool
+type WorkerPoolOptions struct {
+	// Finalizer, if set, is invoked once per worker goroutine right before
+	// it exits
+	Finalizer func()
+}
+
+// WorkerPool is a bounded pool of goroutines, tied to a Worker, that drain
+// a channel of items and run action on each of them
+type WorkerPool[T any] struct {
+	action    func(ctx context.Context, item T) error
+	closed    bool
+	closing   chan struct{}
+	errs      chan error
+	Errors    <-chan error
+	finalizer func()
+	in        chan T
+	m         sync.RWMutex
+	once      sync.Once
+	task      *Task
+	w         *Worker
+}
+
+// NewWorkerPool creates a WorkerPool spawning n worker goroutines as
+// sub-tasks of w, each calling action on every item submitted through
+// Submit/SubmitContext. It's the fan-out primitive for bounded concurrent
+// work on top of Worker/Task.
+func NewWorkerPool[T any](w *Worker, n int, action func(ctx context.Context, item T) error, o ...WorkerPoolOptions) *WorkerPool[T] {
+	var opt WorkerPoolOptions
+	if len(o) > 0 {
+		opt = o[0]
+	}
+
+	errs := make(chan error)
+	p := &WorkerPool[T]{
+		action:    action,
+		closing:   make(chan struct{}),
+		errs:      errs,
+		Errors:    errs,
+		finalizer: opt.Finalizer,
+		in:        make(chan T),
+		task:      w.NewTask(),
+		w:         w,
+	}
+
+	for i := 0; i < n; i++ {
+		p.task.NewSubTask().Do(p.work)
+	}
+
+	// Close when the worker stops
+	w.NewTask().Do(func() {
+		<-w.Context().Done()
+		p.Close()
+	})
+	return p
+}
+
+func (p *WorkerPool[T]) work() {
+	if p.finalizer != nil {
+		defer p.finalizer()
+	}
+	for item := range p.in {
+		p.process(item)
+	}
+}
+
+func (p *WorkerPool[T]) process(item T) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.w.Logger().Errorf("astikit: worker pool action panicked: %v", r)
+		}
+	}()
+
+	if err := p.action(p.w.Context(), item); err != nil {
+		select {
+		case p.errs <- err:
+		case <-p.w.Context().Done():
+		case <-p.closing:
+		}
+	}
+}
+
+// Submit sends item to a worker, blocking until one is available. If the
+// pool is closed (or being closed concurrently), item is silently dropped.
+func (p *WorkerPool[T]) Submit(item T) {
+	_ = p.SubmitContext(p.w.Context(), item)
+}
+
+// SubmitContext is the same as Submit except it gives up and returns
+// ctx.Err() if ctx is done before item is accepted by a worker, and returns
+// ErrWorkerPoolClosed if the pool is closed (or being closed concurrently)
+func (p *WorkerPool[T]) SubmitContext(ctx context.Context, item T) error {
+	// RLock is held for the whole send so that Close, which takes the
+	// write lock before closing the input channel, can never run (and
+	// therefore never close(p.in)) while we're still sending on it
+	p.m.RLock()
+	defer p.m.RUnlock()
+
+	if p.closed {
+		return ErrWorkerPoolClosed
+	}
+
+	select {
+	case p.in <- item:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.closing:
+		return ErrWorkerPoolClosed
+	}
+}
+
+// Close marks the pool as closed, closes its input channel and waits for
+// every worker to finish processing the items already submitted. It's safe
+// to call concurrently with Submit/SubmitContext. Close also stops delivery
+// to Errors: a worker blocked sending an error nobody is reading from
+// Errors is unblocked, so Close can't be made to hang by a caller that
+// doesn't drain Errors.
+func (p *WorkerPool[T]) Close() {
+	p.once.Do(func() {
+		// Closing first unblocks workers stuck sending to Errors and
+		// submitters stuck sending on the input channel while holding the
+		// read lock, so the write lock below can always be acquired
+		close(p.closing)
+
+		p.m.Lock()
+		p.closed = true
+		close(p.in)
+		p.m.Unlock()
+
+		p.task.Wait()
+		p.task.Done()
+		close(p.errs)
+	})
+}
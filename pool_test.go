@@ -0,0 +1,70 @@
+package astikit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWorkerPoolSubmitCloseRace hammers Submit concurrently with Close to
+// make sure Submit never sends on the (by then closed) input channel.
+func TestWorkerPoolSubmitCloseRace(t *testing.T) {
+	w := NewWorker(nil)
+	var processed int64
+	p := NewWorkerPool(w, 4, func(ctx context.Context, item int) error {
+		atomic.AddInt64(&processed, 1)
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				p.Submit(i)
+			}
+		}
+	}()
+
+	// Close races against the still-running Submit loop above; this used
+	// to panic with "send on closed channel" within a few hundred iterations
+	p.Close()
+	close(stop)
+	wg.Wait()
+
+	if err := p.SubmitContext(context.Background(), 0); err != ErrWorkerPoolClosed {
+		t.Fatalf("expected ErrWorkerPoolClosed, got %v", err)
+	}
+}
+
+// TestWorkerPoolCloseDoesntHangOnUndrainedErrors makes sure Close doesn't
+// deadlock when a worker is stuck delivering to Errors and nobody is
+// reading from it.
+func TestWorkerPoolCloseDoesntHangOnUndrainedErrors(t *testing.T) {
+	w := NewWorker(nil)
+	p := NewWorkerPool(w, 1, func(ctx context.Context, item int) error {
+		return errors.New("always fails")
+	})
+
+	p.Submit(0)
+
+	done := make(chan struct{})
+	go func() {
+		p.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close() didn't return: a worker stuck sending to an undrained Errors blocked it")
+	}
+}
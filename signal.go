@@ -0,0 +1,143 @@
+package astikit
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// SignalManager dispatches OS signals and user-defined named signals to
+// registered handlers, serially and with panic recovery, from a single
+// dedicated task. Get one through Worker.Signals.
+type SignalManager struct {
+	emitCh  chan namedSignal
+	m       sync.Mutex
+	namedHs map[string][]*namedHandler
+	osCh    chan os.Signal
+	osHs    map[os.Signal][]*osHandler
+	w       *Worker
+}
+
+type osHandler struct {
+	fn func()
+}
+
+type namedHandler struct {
+	fn func(payload any)
+}
+
+type namedSignal struct {
+	name    string
+	payload any
+}
+
+func newSignalManager(w *Worker) *SignalManager {
+	m := &SignalManager{
+		emitCh:  make(chan namedSignal),
+		namedHs: make(map[string][]*namedHandler),
+		osCh:    make(chan os.Signal, 1),
+		osHs:    make(map[os.Signal][]*osHandler),
+		w:       w,
+	}
+	m.w.NewTask().Do(m.dispatchLoop)
+	return m
+}
+
+// Signals returns the worker's SignalManager, creating it on first call
+func (w *Worker) Signals() *SignalManager {
+	w.sigOnce.Do(func() {
+		w.sig = newSignalManager(w)
+	})
+	return w.sig
+}
+
+func (m *SignalManager) dispatchLoop() {
+	for {
+		select {
+		case s := <-m.osCh:
+			m.m.Lock()
+			hs := append([]*osHandler{}, m.osHs[s]...)
+			m.m.Unlock()
+			for _, h := range hs {
+				m.call(h.fn)
+			}
+		case e := <-m.emitCh:
+			m.m.Lock()
+			hs := append([]*namedHandler{}, m.namedHs[e.name]...)
+			m.m.Unlock()
+			for _, h := range hs {
+				h := h
+				m.call(func() { h.fn(e.payload) })
+			}
+		case <-m.w.Context().Done():
+			return
+		}
+	}
+}
+
+func (m *SignalManager) call(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			m.w.Logger().Errorf("astikit: signal handler panicked: %v", r)
+		}
+	}()
+	fn()
+}
+
+// OnOS registers handler to be called, from the manager's dispatch task,
+// every time sig is received
+func (m *SignalManager) OnOS(sig os.Signal, handler func()) (unregister func()) {
+	m.m.Lock()
+	h := &osHandler{fn: handler}
+	m.osHs[sig] = append(m.osHs[sig], h)
+	m.m.Unlock()
+
+	signal.Notify(m.osCh, sig)
+
+	return func() {
+		m.m.Lock()
+		defer m.m.Unlock()
+		for i, o := range m.osHs[sig] {
+			if o == h {
+				m.osHs[sig] = append(m.osHs[sig][:i], m.osHs[sig][i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// OnNamed registers handler to be called, from the manager's dispatch task,
+// every time name is emitted through Emit
+func (m *SignalManager) OnNamed(name string, handler func(payload any)) (unregister func()) {
+	m.m.Lock()
+	h := &namedHandler{fn: handler}
+	m.namedHs[name] = append(m.namedHs[name], h)
+	m.m.Unlock()
+
+	return func() {
+		m.m.Lock()
+		defer m.m.Unlock()
+		for i, o := range m.namedHs[name] {
+			if o == h {
+				m.namedHs[name] = append(m.namedHs[name][:i], m.namedHs[name][i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Register is an alias of OnNamed, handy when name is chosen dynamically
+// (e.g. a "shutdown" or "reload" signal emitted by an HTTP handler)
+func (m *SignalManager) Register(name string, handler func(payload any)) (unregister func()) {
+	return m.OnNamed(name, handler)
+}
+
+// Emit dispatches name along with payload to every handler registered
+// through OnNamed/Register for that name. It blocks until the worker stops
+// if no dispatch task is listening.
+func (m *SignalManager) Emit(name string, payload any) {
+	select {
+	case m.emitCh <- namedSignal{name: name, payload: payload}:
+	case <-m.w.Context().Done():
+	}
+}
@@ -0,0 +1,171 @@
+package astikit
+
+import (
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestSignalManagerOnOS makes sure a handler registered through OnOS gets
+// called when its signal is emitted, and not when another signal is.
+func TestSignalManagerOnOS(t *testing.T) {
+	w := NewWorker(nil)
+	m := w.Signals()
+
+	called := make(chan struct{}, 1)
+	m.OnOS(syscall.SIGUSR1, func() { called <- struct{}{} })
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatalf("signaling self failed: %v", err)
+	}
+	select {
+	case <-called:
+		t.Fatal("handler should not have been called for an unrelated signal")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("signaling self failed: %v", err)
+	}
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("handler was never called")
+	}
+}
+
+// TestSignalManagerOnOSUnregister makes sure the unregister func returned by
+// OnOS stops the handler from being called.
+func TestSignalManagerOnOSUnregister(t *testing.T) {
+	w := NewWorker(nil)
+	m := w.Signals()
+
+	called := make(chan struct{}, 1)
+	unregister := m.OnOS(syscall.SIGUSR1, func() { called <- struct{}{} })
+	unregister()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("signaling self failed: %v", err)
+	}
+	select {
+	case <-called:
+		t.Fatal("handler should not have been called after being unregistered")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestSignalManagerOnNamedAndEmit makes sure Emit dispatches the payload to
+// every handler registered for that name, and to no other name's handlers.
+func TestSignalManagerOnNamedAndEmit(t *testing.T) {
+	w := NewWorker(nil)
+	m := w.Signals()
+
+	var mu sync.Mutex
+	var got []any
+	m.OnNamed("reload", func(payload any) {
+		mu.Lock()
+		got = append(got, payload)
+		mu.Unlock()
+	})
+
+	done := make(chan struct{})
+	m.OnNamed("other", func(payload any) { close(done) })
+
+	m.Emit("reload", "config.yml")
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("handler was never called")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got[0] != "config.yml" {
+		t.Fatalf("expected payload %q, got %v", "config.yml", got[0])
+	}
+
+	select {
+	case <-done:
+		t.Fatal("handler registered for a different name should not have been called")
+	default:
+	}
+}
+
+// TestSignalManagerRegisterIsOnNamed makes sure Register behaves exactly
+// like OnNamed.
+func TestSignalManagerRegisterIsOnNamed(t *testing.T) {
+	w := NewWorker(nil)
+	m := w.Signals()
+
+	called := make(chan any, 1)
+	unregister := m.Register("greet", func(payload any) { called <- payload })
+
+	m.Emit("greet", "hello")
+	select {
+	case payload := <-called:
+		if payload != "hello" {
+			t.Fatalf("expected payload %q, got %v", "hello", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler was never called")
+	}
+
+	unregister()
+	m.Emit("greet", "again")
+	select {
+	case <-called:
+		t.Fatal("handler should not have been called after being unregistered")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestSignalManagerHandlerPanicRecovered makes sure a panicking handler
+// doesn't take down the dispatch loop, and later handlers keep firing.
+func TestSignalManagerHandlerPanicRecovered(t *testing.T) {
+	w := NewWorker(nil)
+	m := w.Signals()
+
+	m.OnNamed("boom", func(payload any) { panic("kaboom") })
+
+	called := make(chan struct{}, 1)
+	m.OnNamed("after", func(payload any) { called <- struct{}{} })
+
+	m.Emit("boom", nil)
+	m.Emit("after", nil)
+
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("dispatch loop didn't recover from the panicking handler")
+	}
+}
+
+// TestSignalManagerEmitStopsOnWorkerDone makes sure Emit doesn't block
+// forever once the worker has stopped and nothing is dispatching anymore.
+func TestSignalManagerEmitStopsOnWorkerDone(t *testing.T) {
+	w := NewWorker(nil)
+	m := w.Signals()
+	w.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		m.Emit("whatever", nil)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Emit didn't return after the worker stopped")
+	}
+}
@@ -0,0 +1,86 @@
+package astikit
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// TaskInfo describes a named task registered in a Worker's task tree, as
+// returned by Worker.ActiveTasks
+type TaskInfo struct {
+	// Name is the task's own name
+	Name string `json:"name"`
+	// Parent is the name of the task this task was spawned from, empty for
+	// a root task
+	Parent string `json:"parent,omitempty"`
+	// StartedAt is when Do() was called, zero if Do() hasn't been called yet
+	StartedAt time.Time `json:"started_at,omitempty"`
+	// Running indicates whether Do() has been called and the function passed
+	// to it hasn't returned yet
+	Running bool `json:"running"`
+	// Stack is the stack trace of the goroutine that called Do(), useful to
+	// figure out which caller spawned a now-stuck task
+	Stack []string `json:"stack,omitempty"`
+}
+
+// taskRegistry keeps track of every named task spawned from a single Worker,
+// so that Worker.ActiveTasks can expose a live view of the task tree
+type taskRegistry struct {
+	m     sync.Mutex
+	infos map[*Task]*TaskInfo
+}
+
+func newTaskRegistry() *taskRegistry {
+	return &taskRegistry{infos: make(map[*Task]*TaskInfo)}
+}
+
+func (r *taskRegistry) add(t *Task, name, parent string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.infos[t] = &TaskInfo{Name: name, Parent: parent}
+}
+
+func (r *taskRegistry) start(t *Task) {
+	stack := callersStack(3)
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	if i, ok := r.infos[t]; ok {
+		i.StartedAt = time.Now()
+		i.Running = true
+		i.Stack = stack
+	}
+}
+
+func (r *taskRegistry) stop(t *Task) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	delete(r.infos, t)
+}
+
+func (r *taskRegistry) active() (is []TaskInfo) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	is = make([]TaskInfo, 0, len(r.infos))
+	for _, i := range r.infos {
+		is = append(is, *i)
+	}
+	return
+}
+
+// callersStack returns a human-readable stack trace of the calling
+// goroutine, skipping the innermost skip frames
+func callersStack(skip int) (s []string) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		s = append(s, fmt.Sprintf("%s\n\t%s:%d", f.Function, f.File, f.Line))
+		if !more {
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package astikit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWorkerActiveTasksTracksLifecycle makes sure a named task shows up in
+// ActiveTasks once started, carries a stack trace, and disappears once done.
+func TestWorkerActiveTasksTracksLifecycle(t *testing.T) {
+	w := NewWorker(nil)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan struct{})
+	w.NewTaskNamed("my-task").Do(func() {
+		close(started)
+		<-release
+		close(done)
+	})
+	<-started
+
+	is := w.ActiveTasks()
+	if len(is) != 1 {
+		t.Fatalf("expected 1 active task, got %d", len(is))
+	}
+	if is[0].Name != "my-task" {
+		t.Fatalf("expected name %q, got %q", "my-task", is[0].Name)
+	}
+	if !is[0].Running {
+		t.Fatal("expected task to be running")
+	}
+	if is[0].StartedAt.IsZero() {
+		t.Fatal("expected a non-zero StartedAt")
+	}
+	if len(is[0].Stack) == 0 {
+		t.Fatal("expected a non-empty stack trace")
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("task never finished")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if is := w.ActiveTasks(); len(is) == 0 {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("expected no active tasks once done, got %d", len(is))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestWorkerActiveTasksTracksParent makes sure a sub task's parent name is
+// reported correctly.
+func TestWorkerActiveTasksTracksParent(t *testing.T) {
+	w := NewWorker(nil)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	parent := w.NewTaskNamed("parent")
+	parent.Do(func() {
+		parent.NewSubTaskNamed("child").Do(func() {
+			close(started)
+			<-release
+		})
+	})
+	<-started
+
+	var child *TaskInfo
+	for _, i := range w.ActiveTasks() {
+		i := i
+		if i.Name == "child" {
+			child = &i
+		}
+	}
+	if child == nil {
+		t.Fatal("expected to find the child task")
+	}
+	if child.Parent != "parent" {
+		t.Fatalf("expected parent %q, got %q", "parent", child.Parent)
+	}
+
+	close(release)
+}
+
+// TestCallersStack makes sure callersStack returns a non-empty, readable
+// trace that includes its caller.
+func TestCallersStack(t *testing.T) {
+	s := callersStack(2)
+	if len(s) == 0 {
+		t.Fatal("expected a non-empty stack")
+	}
+	if !strings.Contains(s[0], "TestCallersStack") {
+		t.Fatalf("expected the first frame to mention the caller, got %q", s[0])
+	}
+}
@@ -0,0 +1,96 @@
+package astikit
+
+import (
+	"context"
+	"sync"
+)
+
+// waitGroup wraps a sync.WaitGroup with a mutex-guarded counter and a drain
+// flag so that WaitContext can give up on a context deadline without leaving
+// the underlying sync.WaitGroup (and therefore anything still waiting on it)
+// blocked forever because of a leaked Add/Done pair. The counter and the
+// drain flag are always read and mutated together under m, so a task
+// finishing right as WaitContext starts draining can never race with the
+// drain loop over the same underlying sync.WaitGroup slot.
+type waitGroup struct {
+	count    int
+	draining bool
+	m        sync.Mutex
+	wg       sync.WaitGroup
+}
+
+func newWaitGroup() *waitGroup {
+	return &waitGroup{}
+}
+
+// Add adds delta, which may be negative, to the wait group counter. Once
+// draining has started (see WaitContext), the underlying sync.WaitGroup is
+// already being driven down to zero by the drain loop, so Add immediately
+// balances itself against it instead of growing it: otherwise the matching
+// Done would be a no-op (draining) and the real counter would never reach
+// zero again.
+func (g *waitGroup) Add(delta int) {
+	g.m.Lock()
+	if g.draining {
+		g.m.Unlock()
+		return
+	}
+	g.count += delta
+	g.m.Unlock()
+	g.wg.Add(delta)
+}
+
+// Done decrements the wait group counter by one. Once draining has started
+// (see WaitContext), Done becomes a no-op: the waiter itself is responsible
+// for draining the counter down to zero from then on.
+func (g *waitGroup) Done() {
+	g.m.Lock()
+	if g.draining {
+		g.m.Unlock()
+		return
+	}
+	g.count--
+	g.m.Unlock()
+	g.wg.Done()
+}
+
+// Wait blocks until the wait group counter reaches zero
+func (g *waitGroup) Wait() {
+	g.wg.Wait()
+}
+
+// WaitContext blocks until the wait group counter reaches zero or ctx is
+// done, whichever happens first. In the latter case, it switches the wait
+// group into drain mode: Done becomes a no-op for every other caller and
+// WaitContext forcibly drains the counter itself, so a leaked task can no
+// longer block a future Wait/WaitContext call. It returns ctx.Err() if ctx
+// expired first, nil otherwise.
+func (g *waitGroup) WaitContext(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		// From now on, real Done() calls are no-ops: grabbing m here means
+		// no in-flight Done() can still be about to call g.wg.Done() once
+		// we start draining, since it would either have already decremented
+		// g.count and called g.wg.Done() before us, or it's blocked on m
+		// and will see draining=true and become a no-op after us
+		g.m.Lock()
+		g.draining = true
+		n := g.count
+		g.count = 0
+		g.m.Unlock()
+
+		for i := 0; i < n; i++ {
+			g.wg.Done()
+		}
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,83 @@
+package astikit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWaitGroupWaitContextRace races a task's real Done() against a
+// WaitContext whose context is already expired, many times over, to catch
+// a double-decrement of the underlying sync.WaitGroup under -race.
+func TestWaitGroupWaitContextRace(t *testing.T) {
+	for i := 0; i < 20000; i++ {
+		g := newWaitGroup()
+		g.Add(1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			g.Done()
+		}()
+		go func() {
+			defer wg.Done()
+			_ = g.WaitContext(ctx)
+		}()
+		wg.Wait()
+	}
+}
+
+// TestWaitGroupWaitContextTimesOut checks the happy path: WaitContext
+// returns ctx.Err() once ctx expires when Done is never called.
+func TestWaitGroupWaitContextTimesOut(t *testing.T) {
+	g := newWaitGroup()
+	g.Add(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := g.WaitContext(ctx); err != ctx.Err() {
+		t.Fatalf("expected %v, got %v", ctx.Err(), err)
+	}
+
+	// A real Done() call arriving after the drain must not panic nor block
+	// a subsequent Wait()
+	g.Done()
+	g.Wait()
+}
+
+// TestWaitGroupAddAfterDrain makes sure an Add/Done pair arriving after
+// WaitContext has started draining doesn't permanently desync the
+// underlying sync.WaitGroup, which would otherwise hang every future
+// Wait/WaitContext call forever.
+func TestWaitGroupAddAfterDrain(t *testing.T) {
+	g := newWaitGroup()
+	g.Add(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := g.WaitContext(ctx); err != ctx.Err() {
+		t.Fatalf("expected %v, got %v", ctx.Err(), err)
+	}
+
+	g.Add(1)
+	g.Done()
+
+	done := make(chan struct{})
+	go func() {
+		g.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Wait() didn't return: Add() after drain desynced the underlying sync.WaitGroup")
+	}
+}
@@ -2,25 +2,40 @@ package astikit
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"sync"
+	"time"
 )
 
+// DefaultServeGracePeriod is the default grace period given to a served
+// http.Server to shut down once the worker is stopped
+const DefaultServeGracePeriod = 5 * time.Second
+
 // Worker represents an object capable of blocking, handling signals and stopping
 type Worker struct {
-	cancel context.CancelFunc
-	ctx    context.Context
-	l      Logger
-	os, ow sync.Once
-	wg     *sync.WaitGroup
+	cancel           context.CancelFunc
+	ctx              context.Context
+	l                Logger
+	os, ow           sync.Once
+	reg              *taskRegistry
+	serveGracePeriod time.Duration
+	sig              *SignalManager
+	sigOnce          sync.Once
+	wg               *waitGroup
 }
 
 // NewWorker builds a new worker
 func NewWorker(l Logger) (w *Worker) {
 	w = &Worker{
-		l:  newNopLogger(),
-		wg: &sync.WaitGroup{},
+		l:                newNopLogger(),
+		reg:              newTaskRegistry(),
+		serveGracePeriod: DefaultServeGracePeriod,
+		wg:               newWaitGroup(),
 	}
 	w.ctx, w.cancel = context.WithCancel(context.Background())
 	w.wg.Add(1)
@@ -31,7 +46,18 @@ func NewWorker(l Logger) (w *Worker) {
 	return
 }
 
-// HandleSignals handles signals
+// SetServeGracePeriod sets the grace period given to served http.Servers to
+// shut down once the worker is stopped
+func (w *Worker) SetServeGracePeriod(d time.Duration) {
+	w.serveGracePeriod = d
+}
+
+// HandleSignals handles signals. It predates Worker.Signals and is kept
+// as-is for existing callers: unlike SignalManager, it catches every
+// incoming OS signal (not just ones explicitly registered for) and hands
+// each one to every handler in hs, stopping the worker on the first signal
+// isTermSignal considers a termination signal. Prefer Worker.Signals.OnOS
+// for new code that only cares about specific signals.
 func (w *Worker) HandleSignals(hs ...SignalHandler) {
 	// Add default handler
 	hs = append([]SignalHandler{TermSignalHandler(w.Stop)}, hs...)
@@ -64,6 +90,56 @@ func (w *Worker) HandleSignals(hs ...SignalHandler) {
 	})
 }
 
+// Serve starts an HTTP server listening on addr and serving h, tying its
+// lifecycle to the worker: it's started as a managed task and gracefully
+// shut down (using SetServeGracePeriod's duration) when the worker is
+// stopped. It returns as soon as the server is bound, propagating any bind
+// error synchronously.
+func (w *Worker) Serve(addr string, h http.Handler) error {
+	s := &http.Server{Addr: addr, Handler: h}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("astikit: listening on %s failed: %w", addr, err)
+	}
+	w.serve(s, ln, func() error { return s.Serve(ln) })
+	return nil
+}
+
+// ServeTLS is the same as Serve except it serves HTTPS using certFile and
+// keyFile
+func (w *Worker) ServeTLS(addr string, h http.Handler, certFile, keyFile string) error {
+	s := &http.Server{Addr: addr, Handler: h}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("astikit: listening on %s failed: %w", addr, err)
+	}
+	w.serve(s, ln, func() error { return s.ServeTLS(ln, certFile, keyFile) })
+	return nil
+}
+
+// serve runs serveFunc (s.Serve or s.ServeTLS bound to ln) as a managed
+// task and ties s' graceful shutdown to the worker's context
+func (w *Worker) serve(s *http.Server, ln net.Listener, serveFunc func() error) {
+	// Shut the server down when the worker stops
+	w.NewTask().Do(func() {
+		<-w.Context().Done()
+		w.l.Infof("astikit: shutting down server on %s...", s.Addr)
+		ctx, cancel := context.WithTimeout(context.Background(), w.serveGracePeriod)
+		defer cancel()
+		if err := s.Shutdown(ctx); err != nil {
+			w.l.Errorf("astikit: shutting down server on %s failed: %w", s.Addr, err)
+		}
+	})
+
+	// Serve
+	w.NewTask().Do(func() {
+		w.l.Infof("astikit: serving on %s...", s.Addr)
+		if err := serveFunc(); err != nil && err != http.ErrServerClosed {
+			w.l.Errorf("astikit: serving on %s failed: %w", s.Addr, err)
+		}
+	})
+}
+
 // Stop stops the Worker
 func (w *Worker) Stop() {
 	w.os.Do(func() {
@@ -81,9 +157,55 @@ func (w *Worker) Wait() {
 	})
 }
 
+// WaitContext is the same as Wait except it gives up and returns ctx.Err()
+// once ctx is done, instead of blocking forever on tasks that never call
+// Done (e.g. a leaked goroutine)
+func (w *Worker) WaitContext(ctx context.Context) error {
+	w.l.Info("astikit: worker is now waiting...")
+	return w.wg.WaitContext(ctx)
+}
+
 // NewTask creates a new task
 func (w *Worker) NewTask() *Task {
-	return newTask(w.wg)
+	return newTask(w.wg, w.reg)
+}
+
+// NewTaskNamed is the same as NewTask except the task is registered under
+// name in the worker's task tree, so it shows up in ActiveTasks
+func (w *Worker) NewTaskNamed(name string) *Task {
+	t := newTask(w.wg, w.reg)
+	t.name = name
+	w.reg.add(t, name, "")
+	return t
+}
+
+// ActiveTasks returns a live snapshot of every named, not yet completed task
+// in the worker's task tree
+func (w *Worker) ActiveTasks() []TaskInfo {
+	return w.reg.active()
+}
+
+// DebugHandler returns an http.Handler rendering ActiveTasks, useful wired
+// up to a "/debug/tasks"-style endpoint when debugging a hung shutdown. It
+// renders as JSON, or as plain text if the "format=text" query param is set.
+func (w *Worker) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		is := w.ActiveTasks()
+		if r.URL.Query().Get("format") == "text" {
+			rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			for _, i := range is {
+				fmt.Fprintf(rw, "%s (parent: %q, running: %v, started: %s)\n", i.Name, i.Parent, i.Running, i.StartedAt)
+				for _, s := range i.Stack {
+					fmt.Fprintf(rw, "\t%s\n", s)
+				}
+			}
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(rw).Encode(is); err != nil {
+			w.l.Errorf("astikit: encoding active tasks failed: %w", err)
+		}
+	})
 }
 
 // Context returns the worker's context
@@ -98,13 +220,16 @@ func (w *Worker) Logger() Logger {
 
 // Task represents a task
 type Task struct {
+	name    string
 	od, ow  sync.Once
-	wg, pwg *sync.WaitGroup
+	reg     *taskRegistry
+	wg, pwg *waitGroup
 }
 
-func newTask(parentWg *sync.WaitGroup) (t *Task) {
+func newTask(parentWg *waitGroup, reg *taskRegistry) (t *Task) {
 	t = &Task{
-		wg:  &sync.WaitGroup{},
+		reg: reg,
+		wg:  newWaitGroup(),
 		pwg: parentWg,
 	}
 	t.pwg.Add(1)
@@ -116,11 +241,29 @@ type TaskFunc func() *Task
 
 // NewSubTask creates a new sub task
 func (t *Task) NewSubTask() *Task {
-	return newTask(t.wg)
+	return newTask(t.wg, t.reg)
+}
+
+// NewSubTaskNamed is the same as NewSubTask except the sub task is
+// registered under name, with this task's name as its parent, so it shows
+// up in Worker.ActiveTasks
+func (t *Task) NewSubTaskNamed(name string) *Task {
+	st := newTask(t.wg, t.reg)
+	st.name = name
+	if t.reg != nil {
+		t.reg.add(st, name, t.name)
+	}
+	return st
 }
 
 // Do executes the task
 func (t *Task) Do(f func()) {
+	// Capture the caller's stack trace so a stuck task can be traced back
+	// to whoever spawned it
+	if t.name != "" && t.reg != nil {
+		t.reg.start(t)
+	}
+
 	go func() {
 		// Make sure to mark the task as done
 		defer t.Done()
@@ -133,6 +276,9 @@ func (t *Task) Do(f func()) {
 // Done indicates the task is done
 func (t *Task) Done() {
 	t.od.Do(func() {
+		if t.name != "" && t.reg != nil {
+			t.reg.stop(t)
+		}
 		t.pwg.Done()
 	})
 }
@@ -142,4 +288,11 @@ func (t *Task) Wait() {
 	t.ow.Do(func() {
 		t.wg.Wait()
 	})
-}
\ No newline at end of file
+}
+
+// WaitContext is the same as Wait except it gives up and returns ctx.Err()
+// once ctx is done, instead of blocking forever on a sub-task that never
+// calls Done
+func (t *Task) WaitContext(ctx context.Context) error {
+	return t.wg.WaitContext(ctx)
+}